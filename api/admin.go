@@ -0,0 +1,51 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+
+	"github.com/cloudwego/hertz/pkg/app"
+	"github.com/dstgo/tracker/internal/handler"
+	resptypes "github.com/dstgo/tracker/types"
+)
+
+// AdminAPI exposes operator endpoints for kicking off and polling lobby sync cohorts
+// without restarting the cron.
+type AdminAPI struct {
+	LobbyHandler handler.LobbyHandler
+}
+
+// TriggerSync handles POST /admin/sync/trigger. It starts a new sync cohort in the
+// background and returns its ts so the caller can poll SyncStatus. The optional
+// ?limit= query param caps worker concurrency; the handler falls back to
+// handler.WorkerLimit when it's omitted or not a positive integer.
+func (a AdminAPI) TriggerSync(c context.Context, ctx *app.RequestContext) {
+	limit, _ := strconv.Atoi(string(ctx.Query("limit")))
+
+	ts, err := a.LobbyHandler.TriggerSync(c, limit)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, resptypes.Response{Code: http.StatusInternalServerError, Msg: err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, resptypes.Response{Code: http.StatusOK, Data: map[string]any{"ts": ts}})
+}
+
+// SyncStatus handles GET /admin/sync/status, reporting the current outcome counts
+// for the sync cohort identified by the required ?ts= query param.
+func (a AdminAPI) SyncStatus(c context.Context, ctx *app.RequestContext) {
+	ts, err := strconv.ParseInt(string(ctx.Query("ts")), 10, 64)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, resptypes.Response{Code: http.StatusBadRequest, Msg: "invalid ts"})
+		return
+	}
+
+	status, err := a.LobbyHandler.SyncStatus(c, ts)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, resptypes.Response{Code: http.StatusInternalServerError, Msg: err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, resptypes.Response{Code: http.StatusOK, Data: status})
+}
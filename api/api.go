@@ -18,15 +18,20 @@ type API struct {
 func NewRouter(ctx context.Context, hertz *server.Hertz, env *types.Env) (*API, error) {
 
 	hlog.Debug("initializing data repo and creating db index")
-	statisticRepo := repo.NewLobbyStatisticRepo(env.MongoDB)
+	statisticRepo, err := repo.NewLobbyStatisticRepo(ctx, env.MongoDB)
+	if err != nil {
+		return nil, err
+	}
+	syncJobRepo := repo.NewLobbySyncJobRepo(env.MongoDB)
+	snapshotRepo := repo.NewLobbySnapshotRepo(env.MongoDB)
 	// repositories
-	lobbyRepo, err := repo.NewLobbyRepo(ctx, env.MongoDB)
+	lobbyRepo, err := repo.NewLobbyRepo(ctx, env.MongoDB, snapshotRepo)
 	if err != nil {
 		return nil, err
 	}
 
 	// handler
-	lobbyMongoHandler := handler.NewLobbyMongoHandler(lobbyRepo, statisticRepo, env.LobbyCLI, env.GeoIpDB)
+	lobbyMongoHandler := handler.NewLobbyMongoHandler(lobbyRepo, statisticRepo, syncJobRepo, snapshotRepo, env.Redis, env.LobbyCLI, env.GeoIpDB)
 	modHandler := handler.NewWorkShopHandler(env.SteamCLI)
 
 	// system api
@@ -38,11 +43,16 @@ func NewRouter(ctx context.Context, hertz *server.Hertz, env *types.Env) (*API,
 	hertz.GET("/lobby/list", lobbyAPI.List)
 	hertz.GET("/lobby/details", lobbyAPI.Details)
 	hertz.GET("/lobby/stat", lobbyAPI.Statistic)
+	hertz.GET("/lobby/stat/timeseries", lobbyAPI.Timeseries)
+	hertz.GET("/lobby/stat/activity", lobbyAPI.Activity)
 
 	modAPI := ModAPI{modHandler: modHandler}
 	hertz.GET("/mod/search", modAPI.Search)
 
-	// mod api
+	// admin api
+	adminAPI := AdminAPI{LobbyHandler: lobbyMongoHandler}
+	hertz.POST("/admin/sync/trigger", adminAPI.TriggerSync)
+	hertz.GET("/admin/sync/status", adminAPI.SyncStatus)
 
 	return &API{
 		Sys:   sysAPI,
@@ -0,0 +1,46 @@
+package api
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/cloudwego/hertz/pkg/app"
+	"github.com/dstgo/tracker/internal/types"
+	resptypes "github.com/dstgo/tracker/types"
+)
+
+// Timeseries handles GET /lobby/stat/timeseries, returning aggregated lobby rollups
+// for the requested bucket and range, optionally grouped by dimension.
+func (l LobbyAPI) Timeseries(c context.Context, ctx *app.RequestContext) {
+	var options types.QueryLobbyTimeSeriesOptions
+	if err := ctx.BindAndValidate(&options); err != nil {
+		ctx.JSON(http.StatusBadRequest, resptypes.Response{Code: http.StatusBadRequest, Msg: err.Error()})
+		return
+	}
+
+	points, err := l.LobbyHandler.GetTimeSeries(c, options)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, resptypes.Response{Code: http.StatusInternalServerError, Msg: err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, resptypes.Response{Code: http.StatusOK, Data: points})
+}
+
+// Activity handles GET /lobby/stat/activity, returning per-day active/new/gone
+// server counts for a region over the trailing window.
+func (l LobbyAPI) Activity(c context.Context, ctx *app.RequestContext) {
+	var options types.QueryLobbyActivityOptions
+	if err := ctx.BindAndValidate(&options); err != nil {
+		ctx.JSON(http.StatusBadRequest, resptypes.Response{Code: http.StatusBadRequest, Msg: err.Error()})
+		return
+	}
+
+	points, err := l.LobbyHandler.GetActivity(c, options)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, resptypes.Response{Code: http.StatusInternalServerError, Msg: err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, resptypes.Response{Code: http.StatusOK, Data: points})
+}
@@ -2,6 +2,7 @@ package repo
 
 import (
 	"context"
+	"errors"
 	"github.com/dstgo/tracker/internal/types"
 	"github.com/dstgo/tracker/pkg/lobbyapi"
 	"github.com/qiniu/qmgo"
@@ -19,6 +20,11 @@ type LobbyServer struct {
 	PlatformName string   `bson:"platform_name"`
 	TagNames     []string `bson:"tag_names"`
 
+	// SnapshotId ties this row to the sync cohort's lobby_snapshots metadata document,
+	// so the "latest" rows can be selected deterministically instead of by grouping
+	// over created_at.
+	SnapshotId string `bson:"snapshot_id"`
+
 	// created at timestamp
 	CreatedAt       int64 `bson:"created_at"`
 	lobbyapi.Server `bson:"inline"`
@@ -31,7 +37,7 @@ type LobbyServerDetails struct {
 }
 
 // NewLobbyRepo returns new lobby mongo db operator
-func NewLobbyRepo(ctx context.Context, db *qmgo.QmgoClient) (*LobbyRepo, error) {
+func NewLobbyRepo(ctx context.Context, db *qmgo.QmgoClient, snapshotRepo *LobbySnapshotRepo) (*LobbyRepo, error) {
 	col := db.Database.Collection("lobby")
 
 	// create index
@@ -44,18 +50,20 @@ func NewLobbyRepo(ctx context.Context, db *qmgo.QmgoClient) (*LobbyRepo, error)
 		{[]string{"row_id"}, &options.IndexOptions{}},
 		{[]string{"game_mode"}, &options.IndexOptions{}},
 		{[]string{"intent"}, &options.IndexOptions{}},
+		{[]string{"snapshot_id", "row_id"}, &options.IndexOptions{}},
 	})
 
 	if err != nil {
 		return nil, err
 	}
 
-	return &LobbyRepo{cli: db, collection: col}, nil
+	return &LobbyRepo{cli: db, collection: col, snapshotRepo: snapshotRepo}, nil
 }
 
 type LobbyRepo struct {
-	cli        *qmgo.QmgoClient
-	collection *qmgo.Collection
+	cli          *qmgo.QmgoClient
+	collection   *qmgo.Collection
+	snapshotRepo *LobbySnapshotRepo
 }
 
 // RemoveServers returns deletedCount and total count after removing the specified servers
@@ -72,8 +80,13 @@ func (l *LobbyRepo) RemoveServers(ctx context.Context, filter bson.M) (int64, in
 	return result.DeletedCount, estimatedCount, nil
 }
 
-func (l *LobbyRepo) InsertManyServers(ctx context.Context, servers []LobbyServer) (int, error) {
-	// do transaction
+// InsertManyServers stamps every server with snapshotId before inserting, so they can
+// later be selected as one cohort by FindServers.
+func (l *LobbyRepo) InsertManyServers(ctx context.Context, snapshotId string, servers []LobbyServer) (int, error) {
+	for i := range servers {
+		servers[i].SnapshotId = snapshotId
+	}
+
 	result, err := l.collection.InsertMany(ctx, servers)
 	if err != nil {
 		return 0, err
@@ -81,8 +94,11 @@ func (l *LobbyRepo) InsertManyServers(ctx context.Context, servers []LobbyServer
 	return len(result.InsertedIDs), nil
 }
 
-// FindServers returns list of servers by page
-func (l *LobbyRepo) FindServers(ctx context.Context, page, size int, sort string, filter bson.M) (types.PageResult[LobbyServer], error) {
+// FindServers returns a page of servers from a single snapshot. If snapshotId is
+// empty it defaults to the newest completed snapshot recorded in lobby_snapshots,
+// rather than grouping over created_at, which can't tell two overlapping sync
+// cohorts apart and picks an arbitrary bucket.
+func (l *LobbyRepo) FindServers(ctx context.Context, page, size int, sort, snapshotId string, filter bson.M) (types.PageResult[LobbyServer], error) {
 	if page <= 0 {
 		page = 1
 	}
@@ -96,64 +112,48 @@ func (l *LobbyRepo) FindServers(ctx context.Context, page, size int, sort string
 	}
 
 	var result types.PageResult[LobbyServer]
-	lastTs := bson.M{}
-
-	// get the latest inserted timestamp
-	err := l.collection.Aggregate(ctx, qmgo.Pipeline{
-		bson.D{
-			{"$group", bson.M{"_id": "$created_at"}},
-		},
-		bson.D{
-			{"$sort", bson.M{"created": 1}},
-		},
-	}).One(&lastTs)
-
-	if err != nil {
-		return result, err
-	}
 
-	// mean to there has no data in database
-	if len(lastTs) == 0 {
-		return result, nil
+	if snapshotId == "" {
+		snap, err := l.snapshotRepo.Latest(ctx)
+		if err != nil {
+			if errors.Is(err, qmgo.ErrNoSuchDocuments) {
+				// no completed snapshot yet
+				return result, nil
+			}
+			return result, err
+		}
+		snapshotId = snap.SnapshotId
 	}
 
-	// specify latest timestamp
-	ts := lastTs["_id"]
-	filter["created_at"] = ts
+	filter["snapshot_id"] = snapshotId
 
-	// total count
-	total, err := l.collection.Find(ctx, bson.M{"created_at": ts}).EstimatedCount()
+	total, err := l.collection.Find(ctx, filter).Count()
 	if err != nil {
 		return result, err
 	}
 	result.Total = total
 
-	// match
-	matchStage := bson.D{{"$match", filter}}
-	// distinct by grow_id and returns object_id for per item
-	groupStage := bson.D{{"$group", bson.M{"_id": "$row_id", "object_id": bson.M{"$first": "$_id"}}}}
-	// pagination
-	skipStage := bson.D{{"$skip", (page - 1) * size}}
-	limitStage := bson.D{{"$limit", size}}
-
-	// filter results and distinct by row_id, then pagination
-	var objs []bson.M
-	err = l.collection.Aggregate(ctx, qmgo.Pipeline{matchStage, groupStage, skipStage, limitStage}).All(&objs)
+	err = l.collection.Find(ctx, filter).Sort(sort).Skip(int64((page - 1) * size)).Limit(int64(size)).All(&result.List)
 	if err != nil {
 		return result, err
 	}
 
-	// collect object_id
-	var ids []any
-	for _, obj := range objs {
-		ids = append(ids, obj["object_id"])
-	}
-
-	// find final result
-	err = l.collection.Find(ctx, bson.M{"_id": bson.M{"$in": ids}}).All(&result.List)
-	if err != nil {
-		return result, err
-	}
+	// a given (snapshot_id, row_id) pair is expected to be unique, but de-dup
+	// defensively in case a retried sync task inserted the same row_id twice.
+	result.List = dedupByRowId(result.List)
 
 	return result, nil
+}
+
+func dedupByRowId(servers []LobbyServer) []LobbyServer {
+	seen := make(map[string]struct{}, len(servers))
+	deduped := servers[:0]
+	for _, s := range servers {
+		if _, ok := seen[s.RowId]; ok {
+			continue
+		}
+		seen[s.RowId] = struct{}{}
+		deduped = append(deduped, s)
+	}
+	return deduped
 }
\ No newline at end of file
@@ -0,0 +1,44 @@
+package repo
+
+import (
+	"context"
+
+	"github.com/qiniu/qmgo"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// LobbySnapshot is the metadata document recorded atomically once a sync cohort
+// finishes writing its servers, so readers can pick a deterministic "latest" cohort
+// instead of grouping over created_at.
+type LobbySnapshot struct {
+	SnapshotId   string         `bson:"snapshot_id"`
+	Ts           int64          `bson:"ts"`
+	RegionCounts map[string]int `bson:"region_counts"`
+	Total        int            `bson:"total"`
+	CompletedAt  int64          `bson:"completed_at"`
+}
+
+// NewLobbySnapshotRepo returns a new repo backed by the lobby_snapshots collection.
+func NewLobbySnapshotRepo(db *qmgo.QmgoClient) *LobbySnapshotRepo {
+	return &LobbySnapshotRepo{cli: db, collection: db.Database.Collection("lobby_snapshots")}
+}
+
+type LobbySnapshotRepo struct {
+	cli        *qmgo.QmgoClient
+	collection *qmgo.Collection
+}
+
+// RecordSnapshot atomically upserts the metadata document for a completed sync cohort,
+// keyed by snapshot_id so a retried write doesn't create a duplicate entry.
+func (r *LobbySnapshotRepo) RecordSnapshot(ctx context.Context, snap LobbySnapshot) error {
+	_, err := r.collection.Upsert(ctx, bson.M{"snapshot_id": snap.SnapshotId}, bson.M{"$set": snap})
+	return err
+}
+
+// Latest returns the most recently completed snapshot. It returns
+// qmgo.ErrNoSuchDocuments if no snapshot has been recorded yet.
+func (r *LobbySnapshotRepo) Latest(ctx context.Context) (LobbySnapshot, error) {
+	var snap LobbySnapshot
+	err := r.collection.Find(ctx, bson.M{}).Sort("-completed_at").One(&snap)
+	return snap, err
+}
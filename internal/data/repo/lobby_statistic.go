@@ -0,0 +1,430 @@
+package repo
+
+import (
+	"context"
+
+	"github.com/dstgo/tracker/internal/types"
+	"github.com/qiniu/qmgo"
+	opts "github.com/qiniu/qmgo/options"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// StatBucket is the rollup granularity a LobbyStat document was aggregated at.
+type StatBucket string
+
+const (
+	BucketHour StatBucket = "hour"
+	BucketDay  StatBucket = "day"
+)
+
+// TagCount is the occurrence count of a single server tag within a bucket.
+type TagCount struct {
+	Tag   string `bson:"tag"`
+	Count int    `bson:"count"`
+}
+
+// LobbyStat is one aggregated rollup document for a bucketed
+// (region, platform, intent, game_mode) group.
+type LobbyStat struct {
+	BucketStart     int64          `bson:"bucket_start"`
+	Bucket          StatBucket     `bson:"bucket"`
+	Region          string         `bson:"region"`
+	Platform        int            `bson:"platform"`
+	Intent          string         `bson:"intent"`
+	GameMode        string         `bson:"game_mode"`
+	ServerCount     int            `bson:"server_count"`
+	TotalPlayers    int            `bson:"total_players"`
+	MaxPlayersSum   int            `bson:"max_players_sum"`
+	ModEnabledCount int            `bson:"mod_enabled_count"`
+	PasswordCount   int            `bson:"password_count"`
+	Seasons         map[string]int `bson:"seasons"`
+	TopTags         []TagCount     `bson:"top_tags"`
+}
+
+// LobbyPresence tracks the first-seen/last-seen timestamp of a single row_id, so
+// consecutive snapshots can be diffed into active/new/gone activity counts. GoneAt is
+// the timestamp of the first snapshot that dropped this row_id, 0 while it's still
+// being seen; it's what lets CountGone distinguish "gone" from "active" instead of
+// both being derived from the same last_seen range.
+type LobbyPresence struct {
+	RowId     string `bson:"row_id"`
+	Region    string `bson:"region"`
+	FirstSeen int64  `bson:"first_seen"`
+	LastSeen  int64  `bson:"last_seen"`
+	GoneAt    int64  `bson:"gone_at"`
+}
+
+// NewLobbyStatisticRepo returns a new statistic repo backed by the lobby_stat and
+// lobby_presence collections, creating their indexes up front.
+func NewLobbyStatisticRepo(ctx context.Context, db *qmgo.QmgoClient) (*LobbyStatisticRepo, error) {
+	repo := &LobbyStatisticRepo{
+		cli:         db,
+		statCol:     db.Database.Collection("lobby_stat"),
+		presenceCol: db.Database.Collection("lobby_presence"),
+	}
+
+	if err := repo.ensureIndexes(ctx); err != nil {
+		return nil, err
+	}
+
+	return repo, nil
+}
+
+type LobbyStatisticRepo struct {
+	cli         *qmgo.QmgoClient
+	statCol     *qmgo.Collection
+	presenceCol *qmgo.Collection
+}
+
+// ensureIndexes creates the indexes used by time series and presence lookups.
+func (l *LobbyStatisticRepo) ensureIndexes(ctx context.Context) error {
+	if err := l.statCol.CreateIndexes(ctx, []opts.IndexModel{
+		{Key: []string{"bucket", "bucket_start", "region", "platform", "intent", "game_mode"}, IndexOptions: &options.IndexOptions{Unique: boolPtr(true)}},
+		{Key: []string{"bucket_start"}, IndexOptions: &options.IndexOptions{}},
+	}); err != nil {
+		return err
+	}
+
+	return l.presenceCol.CreateIndexes(ctx, []opts.IndexModel{
+		{Key: []string{"row_id"}, IndexOptions: &options.IndexOptions{Unique: boolPtr(true)}},
+		{Key: []string{"region"}, IndexOptions: &options.IndexOptions{}},
+		{Key: []string{"region", "gone_at"}, IndexOptions: &options.IndexOptions{}},
+	})
+}
+
+func boolPtr(b bool) *bool { return &b }
+
+// UpsertRollups aggregates servers into bucket-sized rollup documents and upserts
+// them keyed by (bucket, bucket_start, region, platform, intent, game_mode), so
+// re-running a sync for a bucket that has already been aggregated overwrites rather
+// than duplicates it.
+func (l *LobbyStatisticRepo) UpsertRollups(ctx context.Context, bucket StatBucket, ts int64, servers []LobbyServer) error {
+	bucketStart := truncateToBucket(ts, bucket)
+
+	type groupKey struct {
+		region, intent, gameMode string
+		platform                 int
+	}
+
+	groups := make(map[groupKey]*LobbyStat)
+	tagCounts := make(map[groupKey]map[string]int)
+
+	for _, s := range servers {
+		key := groupKey{region: s.Region, intent: s.Intent, gameMode: s.GameMode, platform: int(s.Platform)}
+		stat, ok := groups[key]
+		if !ok {
+			stat = &LobbyStat{
+				BucketStart: bucketStart,
+				Bucket:      bucket,
+				Region:      key.region,
+				Platform:    key.platform,
+				Intent:      key.intent,
+				GameMode:    key.gameMode,
+				Seasons:     map[string]int{},
+			}
+			groups[key] = stat
+			tagCounts[key] = map[string]int{}
+		}
+
+		stat.ServerCount++
+		stat.TotalPlayers += s.Connected
+		stat.MaxPlayersSum += s.MaxConnections
+		if s.ModEnabled {
+			stat.ModEnabledCount++
+		}
+		if s.HasPassword {
+			stat.PasswordCount++
+		}
+		if s.Season != "" {
+			stat.Seasons[s.Season]++
+		}
+		for _, tag := range s.TagNames {
+			tagCounts[key][tag]++
+		}
+	}
+
+	for key, stat := range groups {
+		stat.TopTags = topNTags(tagCounts[key], 10)
+
+		filter := bson.M{
+			"bucket":       stat.Bucket,
+			"bucket_start": stat.BucketStart,
+			"region":       stat.Region,
+			"platform":     stat.Platform,
+			"intent":       stat.Intent,
+			"game_mode":    stat.GameMode,
+		}
+		if _, err := l.statCol.Upsert(ctx, filter, bson.M{"$set": stat}); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// RecordPresence updates first-seen/last-seen timestamps for every row_id observed in
+// this snapshot, flags previously-tracked row_ids that dropped out of it as gone, and
+// returns the active/new counts for the given region computed by diffing against the
+// previously recorded presence.
+func (l *LobbyStatisticRepo) RecordPresence(ctx context.Context, region string, ts int64, servers []LobbyServer) (active, new_ int, err error) {
+	seen := make(map[string]struct{}, len(servers))
+	for _, s := range servers {
+		if s.Region != region {
+			continue
+		}
+		seen[s.RowId] = struct{}{}
+
+		var existing LobbyPresence
+		getErr := l.presenceCol.Find(ctx, bson.M{"row_id": s.RowId}).One(&existing)
+		if getErr == nil {
+			active++
+			_, err = l.presenceCol.UpdateAll(ctx, bson.M{"row_id": s.RowId}, bson.M{"$set": bson.M{"last_seen": ts, "gone_at": int64(0)}})
+		} else {
+			new_++
+			_, err = l.presenceCol.Upsert(ctx, bson.M{"row_id": s.RowId}, bson.M{"$set": LobbyPresence{
+				RowId: s.RowId, Region: region, FirstSeen: ts, LastSeen: ts,
+			}})
+		}
+		if err != nil {
+			return active, new_, err
+		}
+	}
+
+	if err := l.markGone(ctx, region, ts, seen); err != nil {
+		return active, new_, err
+	}
+
+	return active, new_, nil
+}
+
+// markGone flags presence rows for region that were being tracked (not already gone)
+// but are absent from this snapshot's seen set, recording ts as the moment they
+// dropped out so CountGone can diff by that transition instead of last_seen range.
+func (l *LobbyStatisticRepo) markGone(ctx context.Context, region string, ts int64, seen map[string]struct{}) error {
+	var tracked []LobbyPresence
+	if err := l.presenceCol.Find(ctx, bson.M{"region": region, "gone_at": int64(0)}).All(&tracked); err != nil {
+		return err
+	}
+
+	var missing []string
+	for _, p := range tracked {
+		if _, ok := seen[p.RowId]; !ok {
+			missing = append(missing, p.RowId)
+		}
+	}
+	if len(missing) == 0 {
+		return nil
+	}
+
+	_, err := l.presenceCol.UpdateAll(ctx, bson.M{"row_id": bson.M{"$in": missing}}, bson.M{"$set": bson.M{"gone_at": ts}})
+	return err
+}
+
+// CountGone returns how many servers for region transitioned to gone - dropped out of
+// a snapshot after previously being tracked - within [since, ts).
+func (l *LobbyStatisticRepo) CountGone(ctx context.Context, region string, since, ts int64) (int64, error) {
+	return l.presenceCol.Find(ctx, bson.M{
+		"region":  region,
+		"gone_at": bson.M{"$gte": since, "$lt": ts},
+	}).Count()
+}
+
+// ActivityForWindow returns how many servers were active (last seen) and how many
+// were new (first seen) for region within [windowStart, windowEnd).
+func (l *LobbyStatisticRepo) ActivityForWindow(ctx context.Context, region string, windowStart, windowEnd int64) (active, new_ int, err error) {
+	activeCount, err := l.presenceCol.Find(ctx, bson.M{
+		"region":    region,
+		"last_seen": bson.M{"$gte": windowStart, "$lt": windowEnd},
+	}).Count()
+	if err != nil {
+		return 0, 0, err
+	}
+
+	newCount, err := l.presenceCol.Find(ctx, bson.M{
+		"region":     region,
+		"first_seen": bson.M{"$gte": windowStart, "$lt": windowEnd},
+	}).Count()
+	if err != nil {
+		return 0, 0, err
+	}
+
+	return int(activeCount), int(newCount), nil
+}
+
+// TimeSeries returns rollup points between from and to (inclusive), optionally
+// grouped by a subset of {region, platform, intent, game_mode}.
+func (l *LobbyStatisticRepo) TimeSeries(ctx context.Context, bucket StatBucket, from, to int64, groupBy []string) ([]LobbyStat, error) {
+	filter := bson.M{
+		"bucket":       bucket,
+		"bucket_start": bson.M{"$gte": from, "$lte": to},
+	}
+
+	var stats []LobbyStat
+	query := l.statCol.Find(ctx, filter).Sort("bucket_start")
+	if err := query.All(&stats); err != nil {
+		return nil, err
+	}
+
+	if len(groupBy) == 0 {
+		return stats, nil
+	}
+
+	return mergeStatsByGroup(stats, groupBy), nil
+}
+
+func truncateToBucket(ts int64, bucket StatBucket) int64 {
+	const hourMs = int64(60 * 60 * 1000)
+	const dayMs = 24 * hourMs
+	switch bucket {
+	case BucketDay:
+		return (ts / dayMs) * dayMs
+	default:
+		return (ts / hourMs) * hourMs
+	}
+}
+
+func topNTags(counts map[string]int, n int) []TagCount {
+	tags := make([]TagCount, 0, len(counts))
+	for tag, count := range counts {
+		tags = append(tags, TagCount{Tag: tag, Count: count})
+	}
+	sortTagCounts(tags)
+	if len(tags) > n {
+		tags = tags[:n]
+	}
+	return tags
+}
+
+func sortTagCounts(tags []TagCount) {
+	for i := 1; i < len(tags); i++ {
+		for j := i; j > 0 && tags[j].Count > tags[j-1].Count; j-- {
+			tags[j], tags[j-1] = tags[j-1], tags[j]
+		}
+	}
+}
+
+// mergeStatsByGroup collapses rollups that share the same bucket_start and the
+// requested group_by dimensions, summing their counters.
+func mergeStatsByGroup(stats []LobbyStat, groupBy []string) []LobbyStat {
+	dims := make(map[string]bool, len(groupBy))
+	for _, d := range groupBy {
+		dims[d] = true
+	}
+
+	type key struct {
+		bucketStart                  int64
+		region, intent, gameMode     string
+		platform                     int
+	}
+
+	merged := make(map[key]*LobbyStat)
+	seasons := make(map[key]map[string]int)
+	tagTotals := make(map[key]map[string]int)
+	var order []key
+
+	for _, s := range stats {
+		k := key{bucketStart: s.BucketStart}
+		if dims["region"] {
+			k.region = s.Region
+		}
+		if dims["intent"] {
+			k.intent = s.Intent
+		}
+		if dims["game_mode"] {
+			k.gameMode = s.GameMode
+		}
+		if dims["platform"] {
+			k.platform = s.Platform
+		}
+
+		existing, ok := merged[k]
+		if !ok {
+			existing = &LobbyStat{
+				BucketStart: s.BucketStart,
+				Bucket:      s.Bucket,
+				Region:      k.region,
+				Platform:    k.platform,
+				Intent:      k.intent,
+				GameMode:    k.gameMode,
+			}
+			merged[k] = existing
+			order = append(order, k)
+		}
+
+		existing.ServerCount += s.ServerCount
+		existing.TotalPlayers += s.TotalPlayers
+		existing.MaxPlayersSum += s.MaxPlayersSum
+		existing.ModEnabledCount += s.ModEnabledCount
+		existing.PasswordCount += s.PasswordCount
+		seasons[k] = mergeSeasonCounts(seasons[k], s.Seasons)
+		tagTotals[k] = mergeTagTotals(tagTotals[k], s.TopTags)
+	}
+
+	result := make([]LobbyStat, 0, len(order))
+	for _, k := range order {
+		stat := merged[k]
+		stat.Seasons = seasons[k]
+		stat.TopTags = topNTags(tagTotals[k], 10)
+		result = append(result, *stat)
+	}
+	return result
+}
+
+// mergeSeasonCounts adds src's per-season counts into dst, allocating dst on first use.
+func mergeSeasonCounts(dst map[string]int, src map[string]int) map[string]int {
+	if dst == nil {
+		dst = make(map[string]int, len(src))
+	}
+	for season, count := range src {
+		dst[season] += count
+	}
+	return dst
+}
+
+// mergeTagTotals adds src's already-top-N tag counts into dst, allocating dst on first
+// use. The result is re-ranked by topNTags once every row in the group has been
+// folded in, so a merged point's top tags reflect the whole group rather than
+// whichever row happened to be folded in first.
+func mergeTagTotals(dst map[string]int, src []TagCount) map[string]int {
+	if dst == nil {
+		dst = make(map[string]int, len(src))
+	}
+	for _, t := range src {
+		dst[t.Tag] += t.Count
+	}
+	return dst
+}
+
+// ToTimeSeriesPoints adapts repo rollups into the API-facing response shape.
+func ToTimeSeriesPoints(stats []LobbyStat) []types.LobbyStatPoint {
+	points := make([]types.LobbyStatPoint, 0, len(stats))
+	for _, s := range stats {
+		points = append(points, types.LobbyStatPoint{
+			BucketStart:     s.BucketStart,
+			Region:          s.Region,
+			Platform:        s.Platform,
+			Intent:          s.Intent,
+			GameMode:        s.GameMode,
+			ServerCount:     s.ServerCount,
+			TotalPlayers:    s.TotalPlayers,
+			MaxPlayersSum:   s.MaxPlayersSum,
+			ModEnabledCount: s.ModEnabledCount,
+			PasswordCount:   s.PasswordCount,
+			Seasons:         s.Seasons,
+			TopTags:         toTagCountPoints(s.TopTags),
+		})
+	}
+	return points
+}
+
+func toTagCountPoints(tags []TagCount) []types.TagCount {
+	if len(tags) == 0 {
+		return nil
+	}
+	points := make([]types.TagCount, len(tags))
+	for i, t := range tags {
+		points[i] = types.TagCount{Tag: t.Tag, Count: t.Count}
+	}
+	return points
+}
@@ -0,0 +1,80 @@
+package repo
+
+import (
+	"context"
+
+	"github.com/qiniu/qmgo"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// sync job status values recorded per (region, platform) task attempt.
+const (
+	SyncJobStatusOk         = "ok"
+	SyncJobStatusRetrying   = "retrying"
+	SyncJobStatusDeadLetter = "dead_letter"
+)
+
+// LobbySyncJob is the recorded outcome of a single SyncRegion task attempt, used to
+// inspect a sync cohort's progress from the admin status endpoint.
+type LobbySyncJob struct {
+	Ts           int64  `bson:"ts"`
+	Region       string `bson:"region"`
+	Platform     int    `bson:"platform"`
+	Attempt      int    `bson:"attempt"`
+	FetchedCount int    `bson:"fetched_count"`
+	DurationMs   int64  `bson:"duration_ms"`
+	Status       string `bson:"status"`
+	TimedOut     bool   `bson:"timed_out"`
+	Error        string `bson:"error,omitempty"`
+	CreatedAt    int64  `bson:"created_at"`
+}
+
+// NewLobbySyncJobRepo returns a new repo backed by the lobby_sync_jobs collection.
+func NewLobbySyncJobRepo(db *qmgo.QmgoClient) *LobbySyncJobRepo {
+	return &LobbySyncJobRepo{cli: db, collection: db.Database.Collection("lobby_sync_jobs")}
+}
+
+type LobbySyncJobRepo struct {
+	cli        *qmgo.QmgoClient
+	collection *qmgo.Collection
+}
+
+// RecordOutcome stores one task attempt's outcome.
+func (r *LobbySyncJobRepo) RecordOutcome(ctx context.Context, job LobbySyncJob) error {
+	_, err := r.collection.InsertOne(ctx, job)
+	return err
+}
+
+// CohortStatus summarizes every recorded attempt for the sync cohort started at ts.
+type CohortStatus struct {
+	Ts         int64 `json:"ts"`
+	Total      int   `json:"total"`
+	Ok         int   `json:"ok"`
+	Retrying   int   `json:"retrying"`
+	DeadLetter int   `json:"deadLetter"`
+	Timeouts   int   `json:"timeouts"`
+}
+
+// CohortStatus returns the aggregated outcome counts for the given cohort.
+func (r *LobbySyncJobRepo) CohortStatus(ctx context.Context, ts int64) (CohortStatus, error) {
+	var jobs []LobbySyncJob
+	if err := r.collection.Find(ctx, bson.M{"ts": ts}).All(&jobs); err != nil {
+		return CohortStatus{}, err
+	}
+
+	status := CohortStatus{Ts: ts, Total: len(jobs)}
+	for _, j := range jobs {
+		switch j.Status {
+		case SyncJobStatusOk:
+			status.Ok++
+		case SyncJobStatusDeadLetter:
+			status.DeadLetter++
+		default:
+			status.Retrying++
+		}
+		if j.TimedOut {
+			status.Timeouts++
+		}
+	}
+	return status, nil
+}
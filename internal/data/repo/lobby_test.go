@@ -0,0 +1,78 @@
+package repo
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/dstgo/tracker/pkg/lobbyapi"
+	"github.com/qiniu/qmgo"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// TestFindServers_SnapshotIsolation inserts two overlapping sync cohorts (as could
+// happen if an on-demand /admin/sync/trigger overlaps with the cron) and verifies
+// FindServers returns exactly the rows belonging to the newest completed snapshot,
+// not an arbitrary mix of both.
+func TestFindServers_SnapshotIsolation(t *testing.T) {
+	uri := os.Getenv("TRACKER_TEST_MONGO_URI")
+	if uri == "" {
+		t.Skip("TRACKER_TEST_MONGO_URI not set, skipping mongo integration test")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	db, err := qmgo.Open(ctx, &qmgo.Config{Uri: uri, Database: "tracker_test"})
+	if err != nil {
+		t.Fatalf("connect mongo: %v", err)
+	}
+	defer db.Close(ctx)
+	defer db.Database.Collection("lobby").Drop(ctx)
+	defer db.Database.Collection("lobby_snapshots").Drop(ctx)
+
+	snapshotRepo := NewLobbySnapshotRepo(db)
+	lobbyRepo, err := NewLobbyRepo(ctx, db, snapshotRepo)
+	if err != nil {
+		t.Fatalf("new lobby repo: %v", err)
+	}
+
+	older := []LobbyServer{
+		{Region: "us-east-1", CreatedAt: 1000, Server: lobbyapi.Server{RowId: "row-1", Name: "older-1"}},
+		{Region: "us-east-1", CreatedAt: 1000, Server: lobbyapi.Server{RowId: "row-2", Name: "older-2"}},
+	}
+	newer := []LobbyServer{
+		{Region: "us-east-1", CreatedAt: 2000, Server: lobbyapi.Server{RowId: "row-3", Name: "newer-1"}},
+	}
+
+	if _, err := lobbyRepo.InsertManyServers(ctx, "snapshot-older", older); err != nil {
+		t.Fatalf("insert older cohort: %v", err)
+	}
+	if err := snapshotRepo.RecordSnapshot(ctx, LobbySnapshot{
+		SnapshotId: "snapshot-older", Ts: 1000, Total: len(older), CompletedAt: 1000,
+	}); err != nil {
+		t.Fatalf("record older snapshot: %v", err)
+	}
+
+	if _, err := lobbyRepo.InsertManyServers(ctx, "snapshot-newer", newer); err != nil {
+		t.Fatalf("insert newer cohort: %v", err)
+	}
+	if err := snapshotRepo.RecordSnapshot(ctx, LobbySnapshot{
+		SnapshotId: "snapshot-newer", Ts: 2000, Total: len(newer), CompletedAt: 2000,
+	}); err != nil {
+		t.Fatalf("record newer snapshot: %v", err)
+	}
+
+	result, err := lobbyRepo.FindServers(ctx, 1, 10, "name", "", bson.M{})
+	if err != nil {
+		t.Fatalf("find servers: %v", err)
+	}
+
+	if len(result.List) != 1 {
+		t.Fatalf("expected exactly 1 row from the newest cohort, got %d", len(result.List))
+	}
+	if result.List[0].RowId != "row-3" {
+		t.Fatalf("expected newest cohort's row-3, got %q", result.List[0].RowId)
+	}
+}
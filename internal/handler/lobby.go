@@ -9,11 +9,10 @@ import (
 	"github.com/go-redis/redis/v8"
 	"github.com/oschwald/geoip2-golang"
 	"go.mongodb.org/mongo-driver/bson"
-	"golang.org/x/sync/errgroup"
 	"log/slog"
 	"net"
+	"strconv"
 	"strings"
-	"sync"
 	"time"
 )
 
@@ -29,24 +28,42 @@ type LobbyHandler interface {
 	ClearExpiredServers(ctx context.Context, ttl time.Duration) (int64, int64, error)
 	// GetServerDetails returns details information for specific server
 	GetServerDetails(ctx context.Context, region, rowId string) (types.QueryLobbyServerDetailResp, error)
+	// GetTimeSeries returns aggregated lobby rollups between from and to, optionally grouped by dimension
+	GetTimeSeries(ctx context.Context, options types.QueryLobbyTimeSeriesOptions) ([]types.LobbyStatPoint, error)
+	// GetActivity returns per-day active/new/gone server counts for a region
+	GetActivity(ctx context.Context, options types.QueryLobbyActivityOptions) ([]types.LobbyActivityPoint, error)
+	// TriggerSync enqueues a new sync cohort and returns its ts for status polling
+	TriggerSync(ctx context.Context, limit int) (int64, error)
+	// SyncStatus returns the current job outcome counts for a sync cohort
+	SyncStatus(ctx context.Context, ts int64) (repo.CohortStatus, error)
 }
 
-func NewLobbyMongoHandler(lobbyRepo *repo.LobbyRepo, redis *redis.Client, lobby *lobbyapi.Client, geoip *geoip2.Reader) *LobbyMongoHandler {
+// WorkerLimit is the default number of concurrent workers draining the sync queue when
+// the caller doesn't specify one.
+const WorkerLimit = 8
+
+func NewLobbyMongoHandler(lobbyRepo *repo.LobbyRepo, statisticRepo *repo.LobbyStatisticRepo, syncJobRepo *repo.LobbySyncJobRepo, snapshotRepo *repo.LobbySnapshotRepo, redis *redis.Client, lobby *lobbyapi.Client, geoip *geoip2.Reader) *LobbyMongoHandler {
 	return &LobbyMongoHandler{
-		lobbyRepo: lobbyRepo,
-		redis:     redis,
-		lobby:     lobby,
-		geoip:     geoip,
+		lobbyRepo:     lobbyRepo,
+		statisticRepo: statisticRepo,
+		syncJobRepo:   syncJobRepo,
+		snapshotRepo:  snapshotRepo,
+		redis:         redis,
+		lobby:         lobby,
+		geoip:         geoip,
 	}
 }
 
 var _ LobbyHandler = (*LobbyMongoHandler)(nil)
 
 type LobbyMongoHandler struct {
-	lobbyRepo *repo.LobbyRepo
-	redis     *redis.Client
-	lobby     *lobbyapi.Client
-	geoip     *geoip2.Reader
+	lobbyRepo     *repo.LobbyRepo
+	statisticRepo *repo.LobbyStatisticRepo
+	syncJobRepo   *repo.LobbySyncJobRepo
+	snapshotRepo  *repo.LobbySnapshotRepo
+	redis         *redis.Client
+	lobby         *lobbyapi.Client
+	geoip         *geoip2.Reader
 }
 
 func (l *LobbyMongoHandler) GetServersByPage(ctx context.Context, options types.QueryLobbyServersOptions) (types.PageResult[types.QueryLobbyServersResp], error) {
@@ -96,7 +113,7 @@ func (l *LobbyMongoHandler) GetServersByPage(ctx context.Context, options types.
 
 	var pageResult types.PageResult[types.QueryLobbyServersResp]
 
-	result, err := l.lobbyRepo.FindServers(ctx, options.Page, options.Size, options.Sort, queryM)
+	result, err := l.lobbyRepo.FindServers(ctx, options.Page, options.Size, options.Sort, "", queryM)
 	if err != nil {
 		return pageResult, err
 	}
@@ -110,7 +127,7 @@ func (l *LobbyMongoHandler) GetServerDetails(ctx context.Context, region, rowId
 	var result types.QueryLobbyServerDetailResp
 
 	// get details
-	details, err := l.lobby.GetServerDetails(region, rowId)
+	details, err := l.lobby.GetServerDetailsCtx(ctx, region, rowId)
 	if err != nil {
 		return result, err
 	}
@@ -132,61 +149,68 @@ func (l *LobbyMongoHandler) GetServerDetails(ctx context.Context, region, rowId
 	return result, nil
 }
 
-// GetAllServersFromLobby returns all lobby servers in parallel. Using limit params to limit the number of goroutine
+// GetAllServersFromLobby fetches every region/platform's lobby servers via the redis-backed
+// sync queue, using limit concurrent workers, and returns once the whole cohort has
+// either succeeded or been dead-lettered.
 func (l *LobbyMongoHandler) GetAllServersFromLobby(ctx context.Context, limit int) ([]repo.LobbyServer, error) {
-	slog.Info("begin")
-
-	regions, err := l.lobby.GetCapableRegions()
+	ts, err := l.enqueueCohort(ctx)
 	if err != nil {
 		return nil, err
 	}
 
-	ts := time.Now().UnixMilli()
+	return l.runSyncWorkers(ctx, ts, limit), nil
+}
 
-	var servers []repo.LobbyServer
-	// protect servers []repo.LobbyServer
-	var mu sync.Mutex
+// TriggerSync enqueues a fresh sync cohort (one task per region/platform pair) onto the
+// redis queue and drains it in the background with limit workers (WorkerLimit when
+// limit is not positive), committing and
+// rolling it up the same way SyncLocalServers does, and returns the cohort's ts
+// immediately. This lets an operator kick off a refresh via /admin/sync/trigger and
+// poll /admin/sync/status without restarting the cron.
+func (l *LobbyMongoHandler) TriggerSync(ctx context.Context, limit int) (int64, error) {
+	ts, err := l.enqueueCohort(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	go func() {
+		bgCtx := context.WithoutCancel(ctx)
+		servers := l.runSyncWorkers(bgCtx, ts, limit)
+		if err := l.commitSyncResult(bgCtx, ts, servers); err != nil {
+			slog.Error("commit triggered sync cohort failed", "ts", ts, "error", err)
+		}
+	}()
 
-	group, _ := errgroup.WithContext(ctx)
-	group.SetLimit(limit)
+	return ts, nil
+}
+
+// SyncStatus returns the current job outcome counts for a sync cohort.
+func (l *LobbyMongoHandler) SyncStatus(ctx context.Context, ts int64) (repo.CohortStatus, error) {
+	return l.syncJobRepo.CohortStatus(ctx, ts)
+}
+
+// enqueueCohort pushes one task per (region, platform) pair for a new cohort onto the
+// redis queue and returns its ts.
+func (l *LobbyMongoHandler) enqueueCohort(ctx context.Context) (int64, error) {
+	slog.Info("begin")
+
+	regions, err := l.lobby.GetCapableRegionsCtx(ctx)
+	if err != nil {
+		return 0, err
+	}
 
-	// request servers list from lobby server for each region and platforms
-	// and process list parallelly
+	ts := time.Now().UnixMilli()
+
+	var regionNames []string
 	for _, region := range regions.Regions {
-		for _, platform := range lobbyapi.ExplicitPlatforms {
-			group.Go(func() error {
-				// get servers
-				lobbyServers, err := l.lobby.GetLobbyServers(region.Region, platform)
-				if err != nil {
-					return err
-				}
-
-				// return if list is empty
-				if len(lobbyServers.List) == 0 {
-					return nil
-				}
-
-				// process
-				processList, err := processLobbyServer(lobbyServers.List, l.geoip, region.Region, ts)
-				if err != nil {
-					return err
-				}
-
-				mu.Lock()
-				servers = append(servers, processList...)
-				mu.Unlock()
-
-				return nil
-			})
-		}
+		regionNames = append(regionNames, region.Region)
 	}
 
-	// error occurred
-	if err := group.Wait(); err != nil {
-		return nil, err
+	if err := l.enqueueSyncTasks(ctx, ts, regionNames); err != nil {
+		return 0, err
 	}
 
-	return servers, nil
+	return ts, nil
 }
 
 func (l *LobbyMongoHandler) ClearExpiredServers(ctx context.Context, ttl time.Duration) (int64, int64, error) {
@@ -210,12 +234,140 @@ func (l *LobbyMongoHandler) SyncLocalServers(ctx context.Context, limit int) (in
 		return 0, err
 	}
 
-	// store the server information into mongodb
-	inserted, err := l.lobbyRepo.InsertManyServers(ctx, servers)
+	ts := time.Now().UTC().UnixMilli()
+	if len(servers) > 0 {
+		ts = servers[0].CreatedAt
+	}
+
+	if err := l.commitSyncResult(ctx, ts, servers); err != nil {
+		return 0, err
+	}
+
+	return len(servers), nil
+}
+
+// commitSyncResult commits a completed sync cohort as a new snapshot and rolls it up
+// into the statistic/presence collections, so /lobby/stat/timeseries and
+// /lobby/stat/activity never diverge from what /lobby/list just started serving -
+// whether the cohort came from the cron-driven SyncLocalServers or an operator's
+// on-demand TriggerSync.
+func (l *LobbyMongoHandler) commitSyncResult(ctx context.Context, ts int64, servers []repo.LobbyServer) error {
+	if err := l.commitSnapshot(ctx, ts, servers); err != nil {
+		return err
+	}
+
+	// roll up and diff this snapshot into the statistic collections. the timestamp is
+	// normalized to UTC up front, otherwise a cron catch-up running on a server with a
+	// different local timezone would truncate into the wrong bucket and double-count.
+	if err := l.statisticRepo.UpsertRollups(ctx, repo.BucketHour, ts, servers); err != nil {
+		slog.Error("upsert hourly rollups failed", "error", err)
+	}
+	if err := l.statisticRepo.UpsertRollups(ctx, repo.BucketDay, ts, servers); err != nil {
+		slog.Error("upsert daily rollups failed", "error", err)
+	}
+
+	for _, region := range distinctRegions(servers) {
+		if _, _, err := l.statisticRepo.RecordPresence(ctx, region, ts, servers); err != nil {
+			slog.Error("record presence failed", "region", region, "error", err)
+		}
+	}
+
+	return nil
+}
+
+// commitSnapshot inserts servers tagged with a new snapshot id derived from ts, then
+// atomically records the lobby_snapshots metadata document that makes the cohort
+// visible to FindServers as "latest".
+func (l *LobbyMongoHandler) commitSnapshot(ctx context.Context, ts int64, servers []repo.LobbyServer) error {
+	snapshotId := strconv.FormatInt(ts, 10)
+
+	if _, err := l.lobbyRepo.InsertManyServers(ctx, snapshotId, servers); err != nil {
+		return err
+	}
+
+	return l.snapshotRepo.RecordSnapshot(ctx, repo.LobbySnapshot{
+		SnapshotId:   snapshotId,
+		Ts:           ts,
+		RegionCounts: regionCounts(servers),
+		Total:        len(servers),
+		CompletedAt:  time.Now().UTC().UnixMilli(),
+	})
+}
+
+func regionCounts(servers []repo.LobbyServer) map[string]int {
+	counts := make(map[string]int)
+	for _, s := range servers {
+		counts[s.Region]++
+	}
+	return counts
+}
+
+func distinctRegions(servers []repo.LobbyServer) []string {
+	seen := map[string]struct{}{}
+	var regions []string
+	for _, s := range servers {
+		if _, ok := seen[s.Region]; ok {
+			continue
+		}
+		seen[s.Region] = struct{}{}
+		regions = append(regions, s.Region)
+	}
+	return regions
+}
+
+// GetTimeSeries returns aggregated lobby rollups between from and to, optionally
+// grouped by a subset of {region, platform, intent, game_mode}.
+func (l *LobbyMongoHandler) GetTimeSeries(ctx context.Context, options types.QueryLobbyTimeSeriesOptions) ([]types.LobbyStatPoint, error) {
+	bucket := repo.BucketHour
+	if options.Bucket == string(repo.BucketDay) {
+		bucket = repo.BucketDay
+	}
+
+	var groupBy []string
+	if options.GroupBy != "" {
+		groupBy = strings.Split(options.GroupBy, ",")
+	}
+
+	stats, err := l.statisticRepo.TimeSeries(ctx, bucket, options.From, options.To, groupBy)
 	if err != nil {
-		return inserted, err
+		return nil, err
+	}
+
+	return repo.ToTimeSeriesPoints(stats), nil
+}
+
+// GetActivity returns per-day active/new/gone server counts for a region over the
+// trailing window, computed by diffing consecutive snapshots' presence records.
+func (l *LobbyMongoHandler) GetActivity(ctx context.Context, options types.QueryLobbyActivityOptions) ([]types.LobbyActivityPoint, error) {
+	days := options.Days
+	if days <= 0 {
+		days = 7
+	}
+
+	now := time.Now().UTC()
+	var points []types.LobbyActivityPoint
+	for i := days; i >= 1; i-- {
+		dayStart := now.AddDate(0, 0, -i).Truncate(24 * time.Hour).UnixMilli()
+		dayEnd := dayStart + int64((24 * time.Hour).Milliseconds())
+
+		active, newCount, err := l.statisticRepo.ActivityForWindow(ctx, options.Region, dayStart, dayEnd)
+		if err != nil {
+			return nil, err
+		}
+		gone, err := l.statisticRepo.CountGone(ctx, options.Region, dayStart, dayEnd)
+		if err != nil {
+			return nil, err
+		}
+
+		points = append(points, types.LobbyActivityPoint{
+			Day:    dayStart,
+			Active: active,
+			New:    newCount,
+			Gone:   int(gone),
+		})
 	}
-	return inserted, nil
+
+	return points, nil
 }
 
 func lobbyRepo2Resp(servers []repo.LobbyServer) []types.QueryLobbyServersResp {
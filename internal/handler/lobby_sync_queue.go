@@ -0,0 +1,200 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/dstgo/tracker/internal/data/repo"
+	"github.com/dstgo/tracker/pkg/lobbyapi"
+	"github.com/go-redis/redis/v8"
+)
+
+const (
+	syncQueueKeyPrefix = "tracker:lobby:sync:queue:"
+	syncQueueTTL       = time.Hour
+	syncMaxAttempts    = 5
+	syncPopTimeout     = time.Second
+	// syncRegionDeadline bounds a single region/platform fetch so one bad region can't
+	// monopolize a worker indefinitely and stall the rest of the cohort.
+	syncRegionDeadline = 8 * time.Second
+)
+
+// SyncRegionTask is one (region, platform) unit of sync work pushed onto the
+// redis-backed queue for a given cohort timestamp.
+type SyncRegionTask struct {
+	Region   string            `json:"region"`
+	Platform lobbyapi.Platform `json:"platform"`
+	Ts       int64             `json:"ts"`
+	Attempt  int               `json:"attempt"`
+}
+
+func syncQueueKey(ts int64) string {
+	return fmt.Sprintf("%s%d", syncQueueKeyPrefix, ts)
+}
+
+// enqueueSyncTasks pushes one task per (region, platform) pair for this cohort onto
+// the redis list and refreshes its TTL so an abandoned cohort doesn't linger forever.
+func (l *LobbyMongoHandler) enqueueSyncTasks(ctx context.Context, ts int64, regions []string) error {
+	key := syncQueueKey(ts)
+	for _, region := range regions {
+		for _, platform := range lobbyapi.ExplicitPlatforms {
+			data, err := json.Marshal(SyncRegionTask{Region: region, Platform: platform, Ts: ts})
+			if err != nil {
+				return err
+			}
+			if err := l.redis.RPush(ctx, key, data).Err(); err != nil {
+				return err
+			}
+		}
+	}
+	return l.redis.Expire(ctx, key, syncQueueTTL).Err()
+}
+
+// runSyncWorkers drains the cohort's queue with limit concurrent workers. Each task is
+// retried with exponential backoff up to syncMaxAttempts before being dead-lettered,
+// and every attempt's outcome is recorded to the lobby_sync_jobs collection. It returns
+// once the queue has been empty for syncPopTimeout, meaning the cohort is complete.
+func (l *LobbyMongoHandler) runSyncWorkers(ctx context.Context, ts int64, limit int) []repo.LobbyServer {
+	if limit <= 0 {
+		limit = WorkerLimit
+	}
+
+	key := syncQueueKey(ts)
+	// scoped to this cohort's workers only, so other callers sharing l.lobby (e.g.
+	// GetServerDetailsCtx behind /lobby/details) don't inherit a sync-only deadline.
+	syncClient := l.lobby.WithDeadline(syncRegionDeadline)
+
+	var (
+		mu      sync.Mutex
+		servers []repo.LobbyServer
+		wg      sync.WaitGroup
+	)
+
+	worker := func() {
+		defer wg.Done()
+		for {
+			result, err := l.redis.BLPop(ctx, syncPopTimeout, key).Result()
+			if err == redis.Nil {
+				return
+			}
+			if err != nil {
+				slog.Error("sync queue pop failed", "error", err)
+				return
+			}
+
+			var task SyncRegionTask
+			if err := json.Unmarshal([]byte(result[1]), &task); err != nil {
+				slog.Error("sync task decode failed", "error", err)
+				continue
+			}
+
+			processed, timedOut, err := l.processSyncTask(ctx, syncClient, task)
+			if err != nil {
+				l.retryOrDeadLetter(ctx, key, task, timedOut, err)
+				continue
+			}
+
+			mu.Lock()
+			servers = append(servers, processed...)
+			mu.Unlock()
+		}
+	}
+
+	for i := 0; i < limit; i++ {
+		wg.Add(1)
+		go worker()
+	}
+	wg.Wait()
+
+	return servers
+}
+
+// processSyncTask fetches and processes servers for a single (region, platform) pair
+// using client and records the attempt's outcome.
+func (l *LobbyMongoHandler) processSyncTask(ctx context.Context, client *lobbyapi.Client, task SyncRegionTask) ([]repo.LobbyServer, bool, error) {
+	start := time.Now()
+
+	lobbyServers, err := client.GetLobbyServersCtx(ctx, task.Region, task.Platform)
+	duration := time.Since(start)
+	timedOut := isDeadlineErr(err)
+
+	job := repo.LobbySyncJob{
+		Ts:         task.Ts,
+		Region:     task.Region,
+		Platform:   int(task.Platform),
+		Attempt:    task.Attempt,
+		DurationMs: duration.Milliseconds(),
+		TimedOut:   timedOut,
+		CreatedAt:  time.Now().UnixMilli(),
+	}
+
+	if err != nil {
+		job.Status = repo.SyncJobStatusRetrying
+		job.Error = err.Error()
+		if recErr := l.syncJobRepo.RecordOutcome(ctx, job); recErr != nil {
+			slog.Error("record sync job outcome failed", "error", recErr)
+		}
+		return nil, timedOut, err
+	}
+
+	var processed []repo.LobbyServer
+	if len(lobbyServers.List) > 0 {
+		processed, err = processLobbyServer(lobbyServers.List, l.geoip, task.Region, task.Ts)
+		if err != nil {
+			job.Status = repo.SyncJobStatusRetrying
+			job.Error = err.Error()
+			if recErr := l.syncJobRepo.RecordOutcome(ctx, job); recErr != nil {
+				slog.Error("record sync job outcome failed", "error", recErr)
+			}
+			return nil, false, err
+		}
+	}
+
+	job.Status = repo.SyncJobStatusOk
+	job.FetchedCount = len(processed)
+	if recErr := l.syncJobRepo.RecordOutcome(ctx, job); recErr != nil {
+		slog.Error("record sync job outcome failed", "error", recErr)
+	}
+
+	return processed, false, nil
+}
+
+// retryOrDeadLetter re-queues a failed task with exponential backoff, or records it as
+// dead-lettered once it has exhausted syncMaxAttempts.
+func (l *LobbyMongoHandler) retryOrDeadLetter(ctx context.Context, key string, task SyncRegionTask, timedOut bool, cause error) {
+	task.Attempt++
+	if task.Attempt >= syncMaxAttempts {
+		if recErr := l.syncJobRepo.RecordOutcome(ctx, repo.LobbySyncJob{
+			Ts: task.Ts, Region: task.Region, Platform: int(task.Platform),
+			Attempt: task.Attempt, Status: repo.SyncJobStatusDeadLetter,
+			TimedOut: timedOut, Error: cause.Error(), CreatedAt: time.Now().UnixMilli(),
+		}); recErr != nil {
+			slog.Error("record dead letter outcome failed", "error", recErr)
+		}
+		return
+	}
+
+	time.Sleep(syncBackoff(task.Attempt))
+
+	data, err := json.Marshal(task)
+	if err != nil {
+		slog.Error("encode retried sync task failed", "error", err)
+		return
+	}
+	if err := l.redis.RPush(ctx, key, data).Err(); err != nil {
+		slog.Error("requeue sync task failed", "error", err)
+	}
+}
+
+func syncBackoff(attempt int) time.Duration {
+	return time.Duration(1<<attempt) * time.Second
+}
+
+func isDeadlineErr(err error) bool {
+	return errors.Is(err, context.DeadlineExceeded)
+}
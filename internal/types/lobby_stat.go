@@ -0,0 +1,45 @@
+package types
+
+// TagCount is the occurrence count of a single server tag within a bucket.
+type TagCount struct {
+	Tag   string `json:"tag"`
+	Count int    `json:"count"`
+}
+
+// LobbyStatPoint is one bucketed rollup point returned by the lobby time-series API.
+type LobbyStatPoint struct {
+	BucketStart     int64          `json:"bucketStart"`
+	Region          string         `json:"region,omitempty"`
+	Platform        int            `json:"platform,omitempty"`
+	Intent          string         `json:"intent,omitempty"`
+	GameMode        string         `json:"gameMode,omitempty"`
+	ServerCount     int            `json:"serverCount"`
+	TotalPlayers    int            `json:"totalPlayers"`
+	MaxPlayersSum   int            `json:"maxPlayersSum"`
+	ModEnabledCount int            `json:"modEnabledCount"`
+	PasswordCount   int            `json:"passwordCount"`
+	Seasons         map[string]int `json:"seasons,omitempty"`
+	TopTags         []TagCount     `json:"topTags,omitempty"`
+}
+
+// QueryLobbyTimeSeriesOptions is the parsed query for GET /lobby/stat/timeseries.
+type QueryLobbyTimeSeriesOptions struct {
+	From    int64  `query:"from"`
+	To      int64  `query:"to"`
+	Bucket  string `query:"bucket"`
+	GroupBy string `query:"group_by"`
+}
+
+// QueryLobbyActivityOptions is the parsed query for GET /lobby/stat/activity.
+type QueryLobbyActivityOptions struct {
+	Region string `query:"region"`
+	Days   int    `query:"days"`
+}
+
+// LobbyActivityPoint is one day's active/new/gone server counts for a region.
+type LobbyActivityPoint struct {
+	Day    int64 `json:"day"`
+	Active int   `json:"active"`
+	New    int   `json:"new"`
+	Gone   int   `json:"gone"`
+}
@@ -0,0 +1,124 @@
+package lobbyapi
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+const (
+	defaultBaseURL = "https://lobby-v2-cdn.klei.com"
+	defaultTimeout = 10 * time.Second
+)
+
+// NewClient returns a new Klei lobby API client. A nil httpClient falls back to one
+// with defaultTimeout.
+func NewClient(httpClient *http.Client) *Client {
+	if httpClient == nil {
+		httpClient = &http.Client{Timeout: defaultTimeout}
+	}
+	return &Client{httpClient: httpClient, baseURL: defaultBaseURL}
+}
+
+type Client struct {
+	httpClient *http.Client
+	baseURL    string
+
+	// deadline is the per-call abort window applied by doGet, or 0 for no deadline
+	// beyond the underlying http.Client's own timeout. It's set once, by WithDeadline,
+	// and never mutated afterwards, so reading it needs no synchronization.
+	deadline time.Duration
+}
+
+// WithDeadline returns a copy of the client bounded by a per-call deadline d, leaving
+// the receiver - and any other call path sharing it - untouched. Use this instead of
+// mutating the client in place when only one call path, like a sync cohort, should be
+// bounded: e.g. GetServerDetailsCtx behind /lobby/details keeps running without a
+// deadline even while a sync is using its own WithDeadline-scoped copy.
+func (c *Client) WithDeadline(d time.Duration) *Client {
+	scoped := *c
+	scoped.deadline = d
+	return &scoped
+}
+
+func (c *Client) GetCapableRegions() (CapableRegionsResp, error) {
+	return c.GetCapableRegionsCtx(context.Background())
+}
+
+func (c *Client) GetCapableRegionsCtx(ctx context.Context) (CapableRegionsResp, error) {
+	var resp CapableRegionsResp
+	err := c.doGet(ctx, "/region", nil, &resp)
+	return resp, err
+}
+
+func (c *Client) GetLobbyServers(region string, platform Platform) (LobbyServersResp, error) {
+	return c.GetLobbyServersCtx(context.Background(), region, platform)
+}
+
+func (c *Client) GetLobbyServersCtx(ctx context.Context, region string, platform Platform) (LobbyServersResp, error) {
+	var resp LobbyServersResp
+	query := url.Values{"region": {region}, "platform": {fmt.Sprint(int(platform))}}
+	err := c.doGet(ctx, "/lobby/read", query, &resp)
+	return resp, err
+}
+
+func (c *Client) GetServerDetails(region, rowId string) (ServerDetailsResp, error) {
+	return c.GetServerDetailsCtx(context.Background(), region, rowId)
+}
+
+func (c *Client) GetServerDetailsCtx(ctx context.Context, region, rowId string) (ServerDetailsResp, error) {
+	var resp ServerDetailsResp
+	query := url.Values{"region": {region}, "rowId": {rowId}}
+	err := c.doGet(ctx, "/lobby/read_server_details", query, &resp)
+	return resp, err
+}
+
+// doGet issues the request and, if a deadline is armed, aborts it once the deadline
+// elapses rather than waiting on the http.Client's own (much longer) timeout.
+func (c *Client) doGet(ctx context.Context, path string, query url.Values, out any) error {
+	reqCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	u := c.baseURL + path
+	if query != nil {
+		u += "?" + query.Encode()
+	}
+
+	req, err := http.NewRequestWithContext(reqCtx, http.MethodGet, u, nil)
+	if err != nil {
+		return err
+	}
+
+	type result struct {
+		resp *http.Response
+		err  error
+	}
+	resultCh := make(chan result, 1)
+	go func() {
+		resp, err := c.httpClient.Do(req)
+		resultCh <- result{resp, err}
+	}()
+
+	var cancelCh chan struct{}
+	if c.deadline > 0 {
+		cancelCh = make(chan struct{})
+		timer := time.AfterFunc(c.deadline, func() { close(cancelCh) })
+		defer timer.Stop()
+	}
+
+	select {
+	case res := <-resultCh:
+		if res.err != nil {
+			return res.err
+		}
+		defer res.resp.Body.Close()
+		return json.NewDecoder(res.resp.Body).Decode(out)
+	case <-cancelCh:
+		cancel()
+		<-resultCh // wait for the aborted request to actually return before reusing req
+		return context.DeadlineExceeded
+	}
+}
@@ -0,0 +1,97 @@
+// Package lobbyapi is a thin client for the Klei lobby server browser API, used to
+// discover and inspect Don't Starve Together servers.
+package lobbyapi
+
+// Platform identifies which storefront (Steam, PSN, Xbox, Rail, ...) a lobby server
+// was published to.
+type Platform int
+
+const (
+	PlatformSteam Platform = iota + 1
+	PlatformPSN
+	PlatformXBone
+	PlatformRail
+)
+
+// ExplicitPlatforms is every platform a region is queried for when fanning out a sync.
+var ExplicitPlatforms = []Platform{PlatformSteam, PlatformPSN, PlatformXBone, PlatformRail}
+
+var platformNames = map[Platform]string{
+	PlatformSteam: "Steam",
+	PlatformPSN:   "PSN",
+	PlatformXBone: "Xbox",
+	PlatformRail:  "Rail",
+}
+
+// PlatformDisplayName returns the human readable name for a platform. The region
+// parameter is accepted for forward compatibility with region-specific platform
+// naming (e.g. Rail is region-locked), though every region currently shares one set
+// of names.
+func PlatformDisplayName(region string, platform Platform) string {
+	if name, ok := platformNames[platform]; ok {
+		return name
+	}
+	return "Unknown"
+}
+
+// Server is a single lobby server entry as returned by the Klei lobby service.
+type Server struct {
+	RowId           string   `json:"rowId"`
+	SteamClanId     string   `json:"steamClanID"`
+	Address         string   `json:"__addr"`
+	Port            int      `json:"port"`
+	Host            string   `json:"host"`
+	Platform        Platform `json:"platform"`
+	Version         int      `json:"v"`
+	Name            string   `json:"name"`
+	GameMode        string   `json:"mode"`
+	Intent          string   `json:"intent"`
+	Season          string   `json:"season"`
+	Tags            string   `json:"tags"`
+	MaxConnections  int      `json:"maxconnections"`
+	Connected       int      `json:"connected"`
+	ModEnabled      bool     `json:"mods"`
+	PvpEnabled      bool     `json:"pvp"`
+	HasPassword     bool     `json:"password"`
+	IsDedicated     bool     `json:"dedicated"`
+	ClientHosted    bool     `json:"clienthosted"`
+	AllowNewPlayers bool     `json:"allownewplayers"`
+	ServerPaused    bool     `json:"serverpaused"`
+	FriendOnly      bool     `json:"fo"`
+	ClanOnly        bool     `json:"clanonly"`
+}
+
+// ServerDetails holds the extended fields only returned by the per-server details
+// endpoint.
+type ServerDetails struct {
+	Players  []PlayerSummary `json:"players"`
+	WorldGen string          `json:"worldgen"`
+}
+
+// PlayerSummary is one connected player as reported by a server's details endpoint.
+type PlayerSummary struct {
+	Name   string `json:"name"`
+	Prefab string `json:"prefab"`
+	Colour string `json:"colour"`
+}
+
+// LobbyServersResp is the response body of a lobby read request for one region/platform.
+type LobbyServersResp struct {
+	List []Server `json:"GET"`
+}
+
+// ServerDetailsResp is the response body of a single server's details request.
+type ServerDetailsResp struct {
+	Server  Server
+	Details ServerDetails
+}
+
+// RegionInfo is one region the lobby service currently has servers for.
+type RegionInfo struct {
+	Region string `json:"Region"`
+}
+
+// CapableRegionsResp lists every region capable of serving lobby requests.
+type CapableRegionsResp struct {
+	Regions []RegionInfo `json:"LobbyRegions"`
+}
@@ -6,11 +6,7 @@ import (
 	"github.com/cloudwego/hertz/pkg/app/middlewares/server/recovery"
 	"github.com/cloudwego/hertz/pkg/app/server"
 	"github.com/cloudwego/hertz/pkg/common/hlog"
-	"github.com/cloudwego/hertz/pkg/protocol/consts"
 	"github.com/dstgo/tracker/conf"
-	"github.com/dstgo/tracker/types"
-	"github.com/go-kratos/aegis/ratelimit"
-	"github.com/go-kratos/aegis/ratelimit/bbr"
 	"github.com/go-redis/redis/v8"
 	"github.com/hertz-contrib/cache"
 	"github.com/hertz-contrib/cache/persist"
@@ -39,7 +35,7 @@ func newHttpServer(httpConf conf.HttpConf, client *redis.Client) (*server.Hertz,
 		// recovery handler
 		recoveryHandler(),
 		// request limiter
-		limiterHandler(),
+		limiterHandler(client),
 		// X-Request-ID
 		requestid.New(),
 		// log handler
@@ -48,6 +44,8 @@ func newHttpServer(httpConf conf.HttpConf, client *redis.Client) (*server.Hertz,
 		cacheHandler(client, httpConf),
 	)
 
+	hertz.GET("/admin/limits", adminLimitsHandler())
+
 	return hertz, nil
 }
 
@@ -89,20 +87,3 @@ func cacheHandler(redisCli *redis.Client, httpConf conf.HttpConf) app.HandlerFun
 	cacheH := cache.NewCacheByRequestURIWithIgnoreQueryOrder(store, httpConf.CacheTTL, cache.WithPrefixKey("tracker-cache-"))
 	return cacheH
 }
-
-func limiterHandler() app.HandlerFunc {
-	limiter := bbr.NewLimiter()
-	return func(c context.Context, ctx *app.RequestContext) {
-		done, err := limiter.Allow()
-		if err != nil {
-			ctx.AbortWithStatusJSON(consts.StatusTooManyRequests, types.Response{
-				Code: consts.StatusTooManyRequests,
-				Data: nil,
-				Msg:  "too many requests",
-			})
-		} else {
-			ctx.Next(c)
-			done(ratelimit.DoneInfo{})
-		}
-	}
-}
\ No newline at end of file
@@ -0,0 +1,299 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/cloudwego/hertz/pkg/app"
+	"github.com/cloudwego/hertz/pkg/protocol/consts"
+	"github.com/dstgo/tracker/types"
+	"github.com/go-kratos/aegis/ratelimit"
+	"github.com/go-kratos/aegis/ratelimit/bbr"
+	"github.com/go-redis/redis/v8"
+)
+
+// routeGroup buckets a request path into a coarse group, so a burst of cheap calls to
+// one group can't monopolize the inflight/CPU accounting another group's BBR limiter
+// depends on.
+func routeGroup(path string) string {
+	switch {
+	case strings.HasPrefix(path, "/lobby"):
+		return "/lobby"
+	case strings.HasPrefix(path, "/mod"):
+		return "/mod"
+	case strings.HasPrefix(path, "/admin"):
+		return "/admin"
+	default:
+		return "/ts"
+	}
+}
+
+// routeCost is the per-request token-bucket weight for a route group, roughly
+// proportional to its CPU cost: a `/lobby/list` query should drain a client's budget
+// faster than a `/ts` health check.
+var routeCost = map[string]int64{
+	"/lobby": 5,
+	"/mod":   3,
+	"/admin": 2,
+	"/ts":    1,
+}
+
+func costFor(group string) int64 {
+	if cost, ok := routeCost[group]; ok {
+		return cost
+	}
+	return 1
+}
+
+const (
+	bucketCapacity   = 60 // tokens
+	bucketRefillRate = 1  // tokens per second
+)
+
+// tokenBucketScript atomically refills and debits a per-(client, route group) token
+// bucket stored in redis, so the limit survives across instances rather than resetting
+// whenever a request happens to land on a different process.
+var tokenBucketScript = redis.NewScript(`
+local tokens = tonumber(redis.call("HGET", KEYS[1], "tokens"))
+local ts = tonumber(redis.call("HGET", KEYS[1], "ts"))
+local capacity = tonumber(ARGV[1])
+local refillRate = tonumber(ARGV[2])
+local cost = tonumber(ARGV[3])
+local now = tonumber(ARGV[4])
+
+if tokens == nil then
+	tokens = capacity
+	ts = now
+end
+
+local elapsed = math.max(0, now - ts)
+tokens = math.min(capacity, tokens + (elapsed / 1000.0) * refillRate)
+
+local allowed = 0
+local retryAfterMs = 0
+if tokens >= cost then
+	tokens = tokens - cost
+	allowed = 1
+else
+	retryAfterMs = math.ceil(((cost - tokens) / refillRate) * 1000)
+end
+
+redis.call("HSET", KEYS[1], "tokens", tokens, "ts", now)
+redis.call("PEXPIRE", KEYS[1], 60000)
+
+return {allowed, retryAfterMs}
+`)
+
+// routeLimiters holds one BBR limiter per route group plus its current inflight
+// count, so /admin/limits can report what each group is doing right now.
+type routeLimiters struct {
+	mu       sync.Mutex
+	limiters map[string]ratelimit.Limiter
+	inflight map[string]*int64
+}
+
+func newRouteLimiters() *routeLimiters {
+	return &routeLimiters{
+		limiters: map[string]ratelimit.Limiter{},
+		inflight: map[string]*int64{},
+	}
+}
+
+func (r *routeLimiters) get(group string) (ratelimit.Limiter, *int64) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	limiter, ok := r.limiters[group]
+	if !ok {
+		limiter = bbr.NewLimiter()
+		r.limiters[group] = limiter
+	}
+
+	count, ok := r.inflight[group]
+	if !ok {
+		count = new(int64)
+		r.inflight[group] = count
+	}
+
+	return limiter, count
+}
+
+func (r *routeLimiters) snapshot() map[string]int64 {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	snap := make(map[string]int64, len(r.inflight))
+	for group, count := range r.inflight {
+		snap[group] = atomic.LoadInt64(count)
+	}
+	return snap
+}
+
+// talker is one client IP's observed request volume.
+type talker struct {
+	IP    string `json:"ip"`
+	Count int64  `json:"count"`
+}
+
+const (
+	// talkerCapacity bounds how many distinct client IPs clientTalkers tracks at once,
+	// so a flood of distinct source IPs (or a spoofable X-Forwarded-For) can't grow it
+	// without bound.
+	talkerCapacity = 10000
+	// talkerResetPeriod periodically clears the counts, so /admin/limits reports
+	// recent top talkers rather than an ever-growing, never-decaying tally.
+	talkerResetPeriod = 10 * time.Minute
+)
+
+// clientTalkers tracks how many requests each client IP has made within the current
+// reset window, so /admin/limits can surface the current top talkers.
+type clientTalkers struct {
+	mu      sync.Mutex
+	counts  map[string]int64
+	resetAt time.Time
+}
+
+func newClientTalkers() *clientTalkers {
+	return &clientTalkers{counts: map[string]int64{}, resetAt: time.Now().Add(talkerResetPeriod)}
+}
+
+func (c *clientTalkers) record(ip string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if time.Now().After(c.resetAt) {
+		c.counts = map[string]int64{}
+		c.resetAt = time.Now().Add(talkerResetPeriod)
+	}
+
+	if _, tracked := c.counts[ip]; !tracked && len(c.counts) >= talkerCapacity {
+		// at capacity for a brand new client: drop one arbitrary existing entry
+		// rather than let it grow further. map iteration order is randomized, so
+		// this doesn't consistently favor evicting any particular client.
+		for evict := range c.counts {
+			delete(c.counts, evict)
+			break
+		}
+	}
+
+	c.counts[ip]++
+}
+
+func (c *clientTalkers) top(n int) []talker {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	talkers := make([]talker, 0, len(c.counts))
+	for ip, count := range c.counts {
+		talkers = append(talkers, talker{IP: ip, Count: count})
+	}
+	sort.Slice(talkers, func(i, j int) bool { return talkers[i].Count > talkers[j].Count })
+	if len(talkers) > n {
+		talkers = talkers[:n]
+	}
+	return talkers
+}
+
+var (
+	globalRouteLimiters = newRouteLimiters()
+	globalTalkers       = newClientTalkers()
+)
+
+// limiterHandler replaces a single process-wide BBR limiter with one limiter per
+// route group, plus a redis-backed per-(client_ip, route_group) token bucket. A burst
+// of cheap /ts calls can no longer throttle expensive /lobby/list queries, and a
+// single misbehaving client can no longer starve everyone else in its group.
+func limiterHandler(redisCli *redis.Client) app.HandlerFunc {
+	return func(c context.Context, ctx *app.RequestContext) {
+		group := routeGroup(string(ctx.Path()))
+		ip := ctx.ClientIP()
+		globalTalkers.record(ip)
+
+		limiter, inflight := globalRouteLimiters.get(group)
+		done, err := limiter.Allow()
+		if err != nil {
+			ctx.AbortWithStatusJSON(consts.StatusTooManyRequests, types.Response{
+				Code: consts.StatusTooManyRequests,
+				Data: nil,
+				Msg:  "too many requests",
+			})
+			return
+		}
+
+		atomic.AddInt64(inflight, 1)
+		defer atomic.AddInt64(inflight, -1)
+
+		allowed, retryAfter, bucketErr := takeToken(c, redisCli, ip, group)
+		if bucketErr != nil {
+			// fail open: a redis hiccup shouldn't take the whole API down, the BBR
+			// limiter above still protects the process either way.
+			allowed = true
+		}
+
+		if !allowed {
+			// the BBR limiter let this request in, but the per-client bucket didn't,
+			// so tell it about the rejection to keep its inflight accounting honest.
+			done(ratelimit.DoneInfo{Err: ratelimit.ErrLimitExceed})
+			ctx.Header("Retry-After", strconv.FormatFloat(retryAfter.Seconds(), 'f', 0, 64))
+			ctx.AbortWithStatusJSON(consts.StatusTooManyRequests, types.Response{
+				Code: consts.StatusTooManyRequests,
+				Data: nil,
+				Msg:  "too many requests",
+			})
+			return
+		}
+
+		ctx.Next(c)
+		done(ratelimit.DoneInfo{})
+	}
+}
+
+// takeToken debits cost tokens from the client's bucket for group, returning whether
+// the request is allowed and, if not, how long until enough tokens have refilled.
+func takeToken(ctx context.Context, redisCli *redis.Client, ip, group string) (bool, time.Duration, error) {
+	key := fmt.Sprintf("tracker:ratelimit:%s:%s", group, ip)
+	cost := costFor(group)
+	now := time.Now().UnixMilli()
+
+	result, err := tokenBucketScript.Run(ctx, redisCli, []string{key}, bucketCapacity, bucketRefillRate, cost, now).Result()
+	if err != nil {
+		return false, 0, err
+	}
+
+	values, ok := result.([]interface{})
+	if !ok || len(values) != 2 {
+		return false, 0, fmt.Errorf("ratelimit: unexpected script result %#v", result)
+	}
+
+	allowed, _ := values[0].(int64)
+	retryAfterMs, _ := values[1].(int64)
+
+	return allowed == 1, time.Duration(retryAfterMs) * time.Millisecond, nil
+}
+
+// AdminLimitsResp is the response body for GET /admin/limits.
+type AdminLimitsResp struct {
+	Inflight map[string]int64 `json:"inflight"`
+	Top      []talker         `json:"topTalkers"`
+}
+
+// adminLimitsHandler reports current per-route inflight counts and the top client IPs
+// by request volume, so operators can see who's about to get rate limited.
+func adminLimitsHandler() app.HandlerFunc {
+	return func(c context.Context, ctx *app.RequestContext) {
+		ctx.JSON(http.StatusOK, types.Response{
+			Code: http.StatusOK,
+			Data: AdminLimitsResp{
+				Inflight: globalRouteLimiters.snapshot(),
+				Top:      globalTalkers.top(10),
+			},
+		})
+	}
+}
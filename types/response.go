@@ -0,0 +1,8 @@
+package types
+
+// Response is the standard JSON envelope returned by every HTTP endpoint.
+type Response struct {
+	Code int    `json:"code"`
+	Data any    `json:"data"`
+	Msg  string `json:"msg"`
+}